@@ -22,16 +22,22 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math/big"
 	"os"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	bls_core "github.com/harmony-one/bls/ffi/go/bls"
 	blockfactory "github.com/harmony-one/harmony/block/factory"
 	"github.com/harmony-one/harmony/internal/params"
+	"github.com/harmony-one/harmony/numeric"
+	"github.com/harmony-one/harmony/staking"
 	"github.com/harmony-one/harmony/staking/slash"
 
 	"github.com/harmony-one/harmony/core/rawdb"
@@ -90,6 +96,140 @@ func (ga *GenesisAlloc) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// DecodeStream decodes a Genesis specification from r using token-level
+// streaming for the "alloc" object, so that apart from the resulting Alloc
+// map only one account entry is buffered at a time. This matters for the
+// multi-hundred-MB genesis files typical of forked networks; UnmarshalJSON
+// builds the whole alloc map in memory before copying it in.
+//
+// Other top-level fields are small and still decoded the standard way.
+func (g *Genesis) DecodeStream(r io.Reader) error {
+	dec := json.NewDecoder(r)
+	if t, err := dec.Token(); err != nil {
+		return err
+	} else if d, ok := t.(json.Delim); !ok || d != '{' {
+		return fmt.Errorf("genesis: expected object, got %v", t)
+	}
+
+	other := make(map[string]json.RawMessage)
+	g.Alloc = make(GenesisAlloc)
+	for dec.More() {
+		keyTok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := keyTok.(string)
+		if !ok {
+			return fmt.Errorf("genesis: expected field name, got %v", keyTok)
+		}
+		if key != "alloc" {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return fmt.Errorf("genesis: decoding field %q: %v", key, err)
+			}
+			other[key] = raw
+			continue
+		}
+
+		if t, err := dec.Token(); err != nil {
+			return err
+		} else if d, ok := t.(json.Delim); !ok || d != '{' {
+			return fmt.Errorf("genesis: expected alloc object, got %v", t)
+		}
+		for dec.More() {
+			addrTok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			addrStr, ok := addrTok.(string)
+			if !ok {
+				return fmt.Errorf("genesis: expected alloc address, got %v", addrTok)
+			}
+			var uaddr common.UnprefixedAddress
+			if err := uaddr.UnmarshalText([]byte(addrStr)); err != nil {
+				return fmt.Errorf("genesis: invalid alloc address %q: %v", addrStr, err)
+			}
+			var account GenesisAccount
+			if err := dec.Decode(&account); err != nil {
+				return fmt.Errorf("genesis: decoding account %q: %v", addrStr, err)
+			}
+			g.Alloc[common.Address(uaddr)] = account
+		}
+		if _, err := dec.Token(); err != nil { // consume closing '}' of alloc
+			return err
+		}
+	}
+	if _, err := dec.Token(); err != nil { // consume closing '}' of the genesis object
+		return err
+	}
+
+	if len(other) == 0 {
+		return nil
+	}
+	rest, err := json.Marshal(other)
+	if err != nil {
+		return err
+	}
+	var fields genesisStreamFields
+	if err := json.Unmarshal(rest, &fields); err != nil {
+		return err
+	}
+	g.Config = fields.Config
+	if fields.Config != nil {
+		g.Factory = blockfactory.NewFactory(fields.Config)
+	}
+	g.Nonce = uint64(fields.Nonce)
+	g.ShardID = fields.ShardID
+	g.Timestamp = uint64(fields.Timestamp)
+	g.ExtraData = fields.ExtraData
+	g.GasLimit = uint64(fields.GasLimit)
+	g.Mixhash = fields.Mixhash
+	g.Coinbase = fields.Coinbase
+	g.ShardStateHash = fields.ShardStateHash
+	g.ShardState = fields.ShardState
+	g.Number = uint64(fields.Number)
+	g.GasUsed = uint64(fields.GasUsed)
+	g.ParentHash = fields.ParentHash
+	return nil
+}
+
+// genesisStreamFields holds every Genesis field but Alloc, decoded in one
+// shot by DecodeStream once the streamed alloc entries have been consumed.
+type genesisStreamFields struct {
+	Config         *params.ChainConfig  `json:"config"`
+	Factory        blockfactory.Factory `json:"-"`
+	Nonce          math.HexOrDecimal64  `json:"nonce"`
+	ShardID        uint32               `json:"shardID"`
+	Timestamp      math.HexOrDecimal64  `json:"timestamp"`
+	ExtraData      hexutil.Bytes        `json:"extraData"`
+	GasLimit       math.HexOrDecimal64  `json:"gasLimit"`
+	Mixhash        common.Hash          `json:"mixHash"`
+	Coinbase       common.Address       `json:"coinbase"`
+	ShardStateHash common.Hash          `json:"shardStateHash"`
+	ShardState     shard.State          `json:"shardState"`
+	Number         math.HexOrDecimal64  `json:"number"`
+	GasUsed        math.HexOrDecimal64  `json:"gasUsed"`
+	ParentHash     common.Hash          `json:"parentHash"`
+}
+
+// LoadGenesisFromFile parses a genesis specification from path, preferring
+// the streaming decoder so the file is never held in memory whole and alloc
+// entries are decoded one at a time. Node startup should call this instead of reading the file into a
+// byte slice and calling json.Unmarshal/UnmarshalJSON directly.
+func LoadGenesisFromFile(path string) (*Genesis, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	genesis := new(Genesis)
+	if err := genesis.DecodeStream(f); err != nil {
+		return nil, fmt.Errorf("invalid genesis file %s: %v", path, err)
+	}
+	return genesis, nil
+}
+
 // GenesisAccount is an account in the state of the genesis block.
 type GenesisAccount struct {
 	Code       []byte                      `json:"code,omitempty"`
@@ -97,6 +237,114 @@ type GenesisAccount struct {
 	Balance    *big.Int                    `json:"balance" gencodec:"required"`
 	Nonce      uint64                      `json:"nonce,omitempty"`
 	PrivateKey []byte                      `json:"secretKey,omitempty"` // for tests
+
+	// Validator, when set, registers this account as a staking validator at
+	// genesis, self-delegating SelfDelegation out of Balance.
+	Validator *GenesisValidator `json:"validator,omitempty"`
+	// Delegations pre-seeds delegations made by this account to other
+	// genesis validators, out of Balance.
+	Delegations []GenesisDelegation `json:"delegations,omitempty"`
+}
+
+// GenesisValidator describes the in-protocol staking metadata needed to
+// register a genesis account as a validator, mirroring the subset of
+// staking.Validator that can be known ahead of time from a genesis spec.
+type GenesisValidator struct {
+	BLSPubKeys              []shard.BLSPublicKey `json:"bls-public-keys" gencodec:"required"`
+	MinSelfDelegation       *big.Int             `json:"min-self-delegation" gencodec:"required"`
+	MaxTotalDelegation      *big.Int             `json:"max-total-delegation" gencodec:"required"`
+	CommissionRate          numeric.Dec          `json:"rate" gencodec:"required"`
+	CommissionMaxRate       numeric.Dec          `json:"max-rate" gencodec:"required"`
+	CommissionMaxChangeRate numeric.Dec          `json:"max-change-rate" gencodec:"required"`
+	Name                    string               `json:"name"`
+	Identity                string               `json:"identity"`
+	Website                 string               `json:"website"`
+	SecurityContact         string               `json:"security-contact"`
+	Details                 string               `json:"details"`
+	// SelfDelegation is subtracted from the account's Balance and locked up
+	// as the validator's initial self-delegation.
+	SelfDelegation *big.Int `json:"self-delegation" gencodec:"required"`
+}
+
+// MarshalJSON implements json.Marshaler, encoding the *big.Int fields as hex
+// per the repo's genesis JSON convention (see genesisAccountMarshaling).
+func (v GenesisValidator) MarshalJSON() ([]byte, error) {
+	enc := genesisValidatorMarshaling{
+		BLSPubKeys:              v.BLSPubKeys,
+		MinSelfDelegation:       (*math.HexOrDecimal256)(v.MinSelfDelegation),
+		MaxTotalDelegation:      (*math.HexOrDecimal256)(v.MaxTotalDelegation),
+		CommissionRate:          v.CommissionRate,
+		CommissionMaxRate:       v.CommissionMaxRate,
+		CommissionMaxChangeRate: v.CommissionMaxChangeRate,
+		Name:                    v.Name,
+		Identity:                v.Identity,
+		Website:                 v.Website,
+		SecurityContact:         v.SecurityContact,
+		Details:                 v.Details,
+		SelfDelegation:          (*math.HexOrDecimal256)(v.SelfDelegation),
+	}
+	return json.Marshal(&enc)
+}
+
+// UnmarshalJSON implements json.Unmarshaler; see MarshalJSON.
+func (v *GenesisValidator) UnmarshalJSON(data []byte) error {
+	var dec genesisValidatorMarshaling
+	if err := json.Unmarshal(data, &dec); err != nil {
+		return err
+	}
+	if dec.MinSelfDelegation == nil {
+		return errors.New("missing required field 'min-self-delegation' for GenesisValidator")
+	}
+	if dec.MaxTotalDelegation == nil {
+		return errors.New("missing required field 'max-total-delegation' for GenesisValidator")
+	}
+	if dec.SelfDelegation == nil {
+		return errors.New("missing required field 'self-delegation' for GenesisValidator")
+	}
+	v.BLSPubKeys = dec.BLSPubKeys
+	v.MinSelfDelegation = (*big.Int)(dec.MinSelfDelegation)
+	v.MaxTotalDelegation = (*big.Int)(dec.MaxTotalDelegation)
+	v.CommissionRate = dec.CommissionRate
+	v.CommissionMaxRate = dec.CommissionMaxRate
+	v.CommissionMaxChangeRate = dec.CommissionMaxChangeRate
+	v.Name = dec.Name
+	v.Identity = dec.Identity
+	v.Website = dec.Website
+	v.SecurityContact = dec.SecurityContact
+	v.Details = dec.Details
+	v.SelfDelegation = (*big.Int)(dec.SelfDelegation)
+	return nil
+}
+
+// GenesisDelegation describes a delegation to be applied at genesis, made
+// out of the delegator account's Balance.
+type GenesisDelegation struct {
+	ValidatorAddress common.Address `json:"validator-address" gencodec:"required"`
+	Amount           *big.Int       `json:"amount" gencodec:"required"`
+}
+
+// MarshalJSON implements json.Marshaler, encoding Amount as hex per the
+// repo's genesis JSON convention (see genesisAccountMarshaling).
+func (d GenesisDelegation) MarshalJSON() ([]byte, error) {
+	enc := genesisDelegationMarshaling{
+		ValidatorAddress: d.ValidatorAddress,
+		Amount:           (*math.HexOrDecimal256)(d.Amount),
+	}
+	return json.Marshal(&enc)
+}
+
+// UnmarshalJSON implements json.Unmarshaler; see MarshalJSON.
+func (d *GenesisDelegation) UnmarshalJSON(data []byte) error {
+	var dec genesisDelegationMarshaling
+	if err := json.Unmarshal(data, &dec); err != nil {
+		return err
+	}
+	if dec.Amount == nil {
+		return errors.New("missing required field 'amount' for GenesisDelegation")
+	}
+	d.ValidatorAddress = dec.ValidatorAddress
+	d.Amount = (*big.Int)(dec.Amount)
+	return nil
 }
 
 // field type overrides for gencodec
@@ -119,6 +367,30 @@ type genesisAccountMarshaling struct {
 	PrivateKey hexutil.Bytes
 }
 
+// genesisValidatorMarshaling is the on-the-wire shape of GenesisValidator,
+// with the *big.Int fields swapped for their hex-encoding counterparts.
+type genesisValidatorMarshaling struct {
+	BLSPubKeys              []shard.BLSPublicKey  `json:"bls-public-keys"`
+	MinSelfDelegation       *math.HexOrDecimal256 `json:"min-self-delegation"`
+	MaxTotalDelegation      *math.HexOrDecimal256 `json:"max-total-delegation"`
+	CommissionRate          numeric.Dec           `json:"rate"`
+	CommissionMaxRate       numeric.Dec           `json:"max-rate"`
+	CommissionMaxChangeRate numeric.Dec           `json:"max-change-rate"`
+	Name                    string                `json:"name"`
+	Identity                string                `json:"identity"`
+	Website                 string                `json:"website"`
+	SecurityContact         string                `json:"security-contact"`
+	Details                 string                `json:"details"`
+	SelfDelegation          *math.HexOrDecimal256 `json:"self-delegation"`
+}
+
+// genesisDelegationMarshaling is the on-the-wire shape of GenesisDelegation,
+// with Amount swapped for its hex-encoding counterpart.
+type genesisDelegationMarshaling struct {
+	ValidatorAddress common.Address        `json:"validator-address"`
+	Amount           *math.HexOrDecimal256 `json:"amount"`
+}
+
 // storageJSON represents a 256 bit byte array, but allows less than 256 bits when
 // unmarshaling from hex.
 type storageJSON common.Hash
@@ -158,11 +430,176 @@ func (g *Genesis) configOrDefault(ghash common.Hash) *params.ChainConfig {
 	}
 }
 
+// SetupGenesisBlock writes or updates the genesis block in db.
+// The block that will be used is:
+//
+//	                     genesis == nil       genesis != nil
+//	                  +------------------------------------------
+//	db has no genesis |  main-net default  |  genesis
+//	db has genesis    |  from DB           |  genesis (if compatible)
+//
+// The stored chain configuration will be updated if it is compatible (i.e. does not
+// specify a fork block below the local head block). In case of a conflict, the
+// error is a *params.ConfigCompatError and the new, unwritten config is returned.
+//
+// The returned chain configuration is never nil.
+func SetupGenesisBlock(db ethdb.Database, genesis *Genesis) (*params.ChainConfig, common.Hash, error) {
+	if genesis != nil && genesis.Config == nil {
+		return params.AllProtocolChanges, common.Hash{}, errGenesisNoConfig
+	}
+
+	// Just commit the new block if there is no stored genesis block.
+	stored := rawdb.ReadCanonicalHash(db, 0)
+	if (stored == common.Hash{}) {
+		if genesis == nil {
+			utils.Logger().Info().Msg("Writing default main-net genesis block")
+			genesis = DefaultGenesisBlock()
+		} else {
+			utils.Logger().Info().Msg("Writing custom genesis block")
+		}
+		block, err := genesis.Commit(db)
+		return genesis.Config, block.Hash(), err
+	}
+
+	// We have the genesis block in database, check whether the new genesis (if
+	// provided) is compatible with the one that was already written.
+	if genesis != nil {
+		hash := genesis.ToBlock(nil).Hash()
+		if hash != stored {
+			return genesis.Config, hash, &GenesisMismatchError{stored, hash}
+		}
+	}
+
+	// Get the existing chain configuration.
+	newcfg := genesis.configOrDefault(stored)
+	storedcfg := rawdb.ReadChainConfig(db, stored)
+	if storedcfg == nil {
+		utils.Logger().Warn().Msg("Found genesis block without chain config")
+		rawdb.WriteChainConfig(db, stored, newcfg)
+		return newcfg, stored, nil
+	}
+	// Special case: don't change the existing config of a network if no new config
+	// was supplied. Returning the stored config avoids clobbering it with defaults.
+	if genesis == nil {
+		newcfg = storedcfg
+	}
+	// Check config compatibility and write the config. Compatibility errors are
+	// returned to the caller unless the chain is still at the genesis block.
+	height := rawdb.ReadHeaderNumber(db, rawdb.ReadHeadHeaderHash(db))
+	if height == nil {
+		return newcfg, stored, fmt.Errorf("missing block number for head header hash")
+	}
+	compatErr := storedcfg.CheckCompatible(newcfg, *height)
+	if compatErr != nil && *height != 0 {
+		return newcfg, stored, compatErr
+	}
+	rawdb.WriteChainConfig(db, stored, newcfg)
+	return newcfg, stored, nil
+}
+
+// DefaultGenesisBlock returns the canonical main-net genesis block, used when a
+// node is started without an explicit genesis specification.
+func DefaultGenesisBlock() *Genesis {
+	return &Genesis{
+		Config:    params.MainnetChainConfig,
+		Factory:   blockfactory.NewFactory(params.MainnetChainConfig),
+		GasLimit:  params.GenesisGasLimit,
+		Alloc:     GenesisAlloc{},
+		ShardID:   0,
+		Timestamp: 0,
+	}
+}
+
+// developerFaucetBalance is the amount prefunded to the --dev faucet
+// account: 2^200 wei, comically more than any local testing could spend.
+var developerFaucetBalance = new(big.Int).Lsh(big.NewInt(1), 200)
+
+// developerGenesisMarker stamps dev-mode genesis blocks so they're easy to
+// recognize in logs and block explorers; it is never interpreted on-chain.
+var developerGenesisMarker = []byte("harmony --dev")
+
+// developerValidatorAddress and developerValidatorBLSKey are deterministic
+// so that repeated `harmony --dev` runs produce byte-identical genesis
+// blocks and the same validator/BLS key can be relied on across restarts.
+var (
+	developerValidatorAddress = common.HexToAddress("0x3f1Af9Bd7aE4258f1c6486A644D0Ca7374DD8b65")
+	developerValidatorBLSKey  = deterministicBLSPublicKey("harmony --dev validator")
+)
+
+// deterministicBLSPublicKey derives a fixed BLS secret key from seed via
+// bls.SecretKey.SetHashOf (so the result is always a valid scalar, unlike
+// truncating a raw digest into the public key bytes) and returns the
+// corresponding public key. The secret key itself is discarded here; it can
+// be re-derived from the same seed with SetHashOf. Never use this outside of
+// developer mode: the seed is public, so the "private" key is too.
+func deterministicBLSPublicKey(seed string) shard.BLSPublicKey {
+	var secretKey bls_core.SecretKey
+	secretKey.SetHashOf([]byte(seed))
+	var key shard.BLSPublicKey
+	copy(key[:], secretKey.GetPublicKey().Serialize())
+	return key
+}
+
+// DeveloperGenesisBlock returns a ready-to-run single-shard genesis for
+// `harmony --dev`: a prefunded faucet account and a deterministic
+// self-staked validator, with a short epoch length and a high gas limit so
+// local smart-contract testing doesn't need the full localnet config.
+// period is the target seconds-per-block, kept for interface parity with
+// geth's --dev flow; it is not otherwise interpreted here.
+func DeveloperGenesisBlock(period uint64, faucet common.Address, shardID uint32) *Genesis {
+	return DeveloperGenesisBlockWithAlloc(period, faucet, shardID, nil)
+}
+
+// DeveloperGenesisBlockWithAlloc is DeveloperGenesisBlock plus a set of
+// additional prefunded addresses, for tests that need more than one funded
+// account.
+func DeveloperGenesisBlockWithAlloc(period uint64, faucet common.Address, shardID uint32, extra []common.Address) *Genesis {
+	config := *params.AllProtocolChanges
+	config.BlocksPerEpoch = 10 // short epochs so staking/committee logic is exercisable quickly
+
+	alloc := GenesisAlloc{
+		faucet: {Balance: developerFaucetBalance},
+		developerValidatorAddress: {
+			Balance: developerFaucetBalance,
+			Validator: &GenesisValidator{
+				BLSPubKeys:              []shard.BLSPublicKey{developerValidatorBLSKey},
+				MinSelfDelegation:       big.NewInt(1),
+				MaxTotalDelegation:      developerFaucetBalance,
+				CommissionRate:          numeric.ZeroDec(),
+				CommissionMaxRate:       numeric.OneDec(),
+				CommissionMaxChangeRate: numeric.OneDec(),
+				Name:                    "harmony-dev",
+				Identity:                "harmony-dev-validator",
+				SelfDelegation:          big.NewInt(1),
+			},
+		},
+	}
+	for _, addr := range extra {
+		if _, exists := alloc[addr]; exists {
+			continue
+		}
+		alloc[addr] = GenesisAccount{Balance: developerFaucetBalance}
+	}
+
+	return &Genesis{
+		Config:    &config,
+		Factory:   blockfactory.NewFactory(&config),
+		GasLimit:  80000000,
+		Alloc:     alloc,
+		ShardID:   shardID,
+		Timestamp: 0,
+		ExtraData: developerGenesisMarker,
+	}
+}
+
 // ToBlock creates the genesis block and writes state of a genesis specification
 // to the given database (or discards it if nil).
 func (g *Genesis) ToBlock(db ethdb.Database) *types.Block {
 	if db == nil {
-		utils.Logger().Error().Msg("db should be initialized")
+		db = rawdb.NewMemoryDatabase()
+	}
+	if err := g.validateAlloc(); err != nil {
+		utils.Logger().Error().Err(err).Msg("invalid genesis alloc")
 		os.Exit(1)
 	}
 	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
@@ -174,6 +611,34 @@ func (g *Genesis) ToBlock(db ethdb.Database) *types.Block {
 			statedb.SetState(addr, key, value)
 		}
 	}
+	// Validators must all be registered before any delegation is applied, since a
+	// delegation may target a validator that sorts after it in map iteration order.
+	for addr, account := range g.Alloc {
+		if account.Validator == nil {
+			continue
+		}
+		wrapper := newGenesisValidatorWrapper(addr, account.Validator)
+		if err := state.UpdateValidatorWrapper(statedb, addr, wrapper); err != nil {
+			utils.Logger().Error().Err(err).Str("validator", addr.Hex()).Msg("failed to write genesis validator")
+			os.Exit(1)
+		}
+		statedb.SubBalance(addr, account.Validator.SelfDelegation)
+	}
+	for addr, account := range g.Alloc {
+		for _, delegation := range account.Delegations {
+			wrapper, err := state.ValidatorWrapper(statedb, delegation.ValidatorAddress)
+			if err != nil {
+				utils.Logger().Error().Err(err).Str("validator", delegation.ValidatorAddress.Hex()).Msg("genesis delegation references unknown validator")
+				os.Exit(1)
+			}
+			wrapper.Delegations = append(wrapper.Delegations, staking.NewDelegation(addr, delegation.Amount))
+			if err := state.UpdateValidatorWrapper(statedb, delegation.ValidatorAddress, wrapper); err != nil {
+				utils.Logger().Error().Err(err).Str("validator", delegation.ValidatorAddress.Hex()).Msg("failed to write genesis delegation")
+				os.Exit(1)
+			}
+			statedb.SubBalance(addr, delegation.Amount)
+		}
+	}
 	root := statedb.IntermediateRoot(false)
 	shardStateBytes, err := shard.EncodeWrapper(g.ShardState, false)
 	if err != nil {
@@ -200,6 +665,67 @@ func (g *Genesis) ToBlock(db ethdb.Database) *types.Block {
 	return types.NewBlock(head, nil, nil, nil, nil, nil)
 }
 
+// validateAlloc checks the staking-related invariants of the genesis alloc:
+// every delegation (including a validator's own self-delegation) must be
+// covered by the account's balance, and no BLS public key may be reused
+// across validators.
+func (g *Genesis) validateAlloc() error {
+	seenKeys := make(map[shard.BLSPublicKey]common.Address)
+	for addr, account := range g.Alloc {
+		total := big.NewInt(0)
+		if account.Validator != nil {
+			total.Add(total, account.Validator.SelfDelegation)
+			for _, key := range account.Validator.BLSPubKeys {
+				if other, ok := seenKeys[key]; ok {
+					return fmt.Errorf("duplicate BLS public key %s used by %s and %s", key.Hex(), other.Hex(), addr.Hex())
+				}
+				seenKeys[key] = addr
+			}
+		}
+		for _, delegation := range account.Delegations {
+			total.Add(total, delegation.Amount)
+		}
+		if total.Cmp(account.Balance) > 0 {
+			return fmt.Errorf("account %s delegates %s but only has balance %s", addr.Hex(), total.String(), account.Balance.String())
+		}
+	}
+	return nil
+}
+
+// newGenesisValidatorWrapper builds the staking.ValidatorWrapper to be
+// persisted for a genesis validator account.
+func newGenesisValidatorWrapper(addr common.Address, v *GenesisValidator) *staking.ValidatorWrapper {
+	return &staking.ValidatorWrapper{
+		Validator: staking.Validator{
+			Address:              addr,
+			SlotPubKeys:          v.BLSPubKeys,
+			LastEpochInCommittee: big.NewInt(0),
+			MinSelfDelegation:    v.MinSelfDelegation,
+			MaxTotalDelegation:   v.MaxTotalDelegation,
+			Active:               true,
+			Commission: staking.Commission{
+				CommissionRates: staking.CommissionRates{
+					Rate:          v.CommissionRate,
+					MaxRate:       v.CommissionMaxRate,
+					MaxChangeRate: v.CommissionMaxChangeRate,
+				},
+				UpdateHeight: big.NewInt(0),
+			},
+			Description: staking.Description{
+				Name:            v.Name,
+				Identity:        v.Identity,
+				Website:         v.Website,
+				SecurityContact: v.SecurityContact,
+				Details:         v.Details,
+			},
+			CreationHeight: big.NewInt(0),
+		},
+		Delegations: []staking.Delegation{
+			staking.NewDelegation(addr, v.SelfDelegation),
+		},
+	}
+}
+
 // Commit writes the block and state of a genesis specification to the database.
 // The block is committed as the canonical head block.
 func (g *Genesis) Commit(db ethdb.Database) (*types.Block, error) {
@@ -214,6 +740,18 @@ func (g *Genesis) Commit(db ethdb.Database) (*types.Block, error) {
 	rawdb.WriteHeadBlockHash(db, block.Hash())
 	rawdb.WriteHeadHeaderHash(db, block.Hash())
 
+	var validators []common.Address
+	for addr, account := range g.Alloc {
+		if account.Validator != nil {
+			validators = append(validators, addr)
+		}
+	}
+	if len(validators) > 0 {
+		if err := rawdb.WriteValidatorList(db, validators); err != nil {
+			utils.Logger().Error().Err(err).Msg("Failed to store genesis validator list")
+		}
+	}
+
 	err := rawdb.WriteShardStateBytes(db, block.Header().Epoch(), block.Header().ShardState())
 
 	if err != nil {
@@ -245,3 +783,233 @@ func (g *Genesis) MustCommit(db ethdb.Database) *types.Block {
 	}
 	return block
 }
+
+// genesisHeader holds every field DumpGenesis/DumpGenesisToJSON need to
+// reconstruct a Genesis other than the alloc, read once from the stored
+// header/config/shard-state rather than duplicated in both functions.
+type genesisHeader struct {
+	config     *params.ChainConfig
+	header     *types.Header
+	shardState *shard.State
+}
+
+func readGenesisHeader(db ethdb.Database, blockHash common.Hash) (*genesisHeader, error) {
+	number := rawdb.ReadHeaderNumber(db, blockHash)
+	if number == nil {
+		return nil, fmt.Errorf("missing header for block %x", blockHash)
+	}
+	header := rawdb.ReadHeader(db, blockHash, *number)
+	if header == nil {
+		return nil, fmt.Errorf("missing header for block %x", blockHash)
+	}
+	config := rawdb.ReadChainConfig(db, blockHash)
+	if config == nil {
+		return nil, fmt.Errorf("missing chain config for block %x", blockHash)
+	}
+	shardStateBytes := rawdb.ReadShardState(db, header.Epoch())
+	shardState, err := shard.DecodeWrapper(shardStateBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode shard state for block %x: %v", blockHash, err)
+	}
+	return &genesisHeader{config: config, header: header, shardState: shardState}, nil
+}
+
+func (h *genesisHeader) toGenesis(alloc GenesisAlloc) *Genesis {
+	return &Genesis{
+		Config:         h.config,
+		Factory:        blockfactory.NewFactory(h.config),
+		Nonce:          h.header.Nonce().Uint64(),
+		ShardID:        h.header.ShardID(),
+		Timestamp:      h.header.Time().Uint64(),
+		ExtraData:      h.header.Extra(),
+		GasLimit:       h.header.GasLimit(),
+		Mixhash:        h.header.MixDigest(),
+		Coinbase:       h.header.Coinbase(),
+		Alloc:          alloc,
+		ShardStateHash: h.header.ShardStateHash(),
+		ShardState:     *h.shardState,
+		Number:         h.header.Number().Uint64(),
+		GasUsed:        h.header.GasUsed(),
+		ParentHash:     h.header.ParentHash(),
+	}
+}
+
+// DumpGenesis reconstructs a Genesis specification from the chain state
+// stored for blockHash, so it can be re-serialized as a genesis.json (for
+// snapshot-based bootstrapping or regenerating a genesis after a hard fork).
+// It materializes the full alloc in memory; for a state too large to hold
+// at once, use DumpGenesisToJSON instead.
+func DumpGenesis(db ethdb.Database, blockHash common.Hash) (*Genesis, error) {
+	gh, err := readGenesisHeader(db, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	statedb, err := state.New(gh.header.Root(), state.NewDatabase(db))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state trie at block %x: %v", blockHash, err)
+	}
+
+	alloc := make(GenesisAlloc)
+	dump := statedb.RawDump()
+	for addrHex, account := range dump.Accounts {
+		addr := common.HexToAddress(addrHex)
+		balance, ok := new(big.Int).SetString(account.Balance, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid balance for account %s: %q", addrHex, account.Balance)
+		}
+		code, err := hex.DecodeString(account.Code)
+		if err != nil {
+			return nil, fmt.Errorf("invalid code for account %s: %v", addrHex, err)
+		}
+		storage := make(map[common.Hash]common.Hash, len(account.Storage))
+		for k, v := range account.Storage {
+			storage[common.HexToHash(k)] = common.HexToHash(v)
+		}
+		alloc[addr] = GenesisAccount{
+			Code:    code,
+			Storage: storage,
+			Balance: balance,
+			Nonce:   account.Nonce,
+		}
+	}
+
+	return gh.toGenesis(alloc), nil
+}
+
+// ExportGenesis reconstructs the Genesis specification for the given block
+// hash from bc's underlying database. See DumpGenesis.
+func (bc *BlockChain) ExportGenesis(blockHash common.Hash) (*Genesis, error) {
+	return DumpGenesis(bc.ChainDb(), blockHash)
+}
+
+// emptyRoot is the known root hash of a trie holding no entries; an account
+// whose storage root equals it has no storage worth iterating.
+var emptyRoot = common.HexToHash("56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
+
+// emptyCodeHash is the hash of an empty byte slice; an account whose code
+// hash equals it is a plain account with no contract code to look up.
+var emptyCodeHash = crypto.Keccak256(nil)
+
+// DumpGenesisToJSON writes the Genesis reconstructed from blockHash as JSON
+// to w, iterating the account trie directly and encoding each account as it
+// is visited — unlike DumpGenesis, which calls statedb.RawDump and holds
+// the whole alloc in memory before returning. This keeps memory bounded by
+// a single account (plus that account's own storage trie) regardless of
+// how large the state is.
+func DumpGenesisToJSON(w io.Writer, db ethdb.Database, blockHash common.Hash) error {
+	gh, err := readGenesisHeader(db, blockHash)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, `{`); err != nil {
+		return err
+	}
+	headerFields := struct {
+		Config         *params.ChainConfig `json:"config"`
+		Nonce          hexutil.Uint64      `json:"nonce"`
+		ShardID        uint32              `json:"shardID"`
+		Timestamp      hexutil.Uint64      `json:"timestamp"`
+		ExtraData      hexutil.Bytes       `json:"extraData"`
+		GasLimit       hexutil.Uint64      `json:"gasLimit"`
+		Mixhash        common.Hash         `json:"mixHash"`
+		Coinbase       common.Address      `json:"coinbase"`
+		ShardStateHash common.Hash         `json:"shardStateHash"`
+		ShardState     shard.State         `json:"shardState"`
+		Number         hexutil.Uint64      `json:"number"`
+		GasUsed        hexutil.Uint64      `json:"gasUsed"`
+		ParentHash     common.Hash         `json:"parentHash"`
+	}{
+		gh.config, hexutil.Uint64(gh.header.Nonce().Uint64()), gh.header.ShardID(), hexutil.Uint64(gh.header.Time().Uint64()),
+		gh.header.Extra(), hexutil.Uint64(gh.header.GasLimit()), gh.header.MixDigest(), gh.header.Coinbase(),
+		gh.header.ShardStateHash(), *gh.shardState, hexutil.Uint64(gh.header.Number().Uint64()), hexutil.Uint64(gh.header.GasUsed()),
+		gh.header.ParentHash(),
+	}
+	headerJSON, err := json.Marshal(headerFields)
+	if err != nil {
+		return err
+	}
+	// Strip the enclosing braces so the fields can be followed by "alloc".
+	if _, err := w.Write(headerJSON[1 : len(headerJSON)-1]); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, `,"alloc":{`); err != nil {
+		return err
+	}
+
+	accountTrie, err := trie.NewSecure(gh.header.Root(), trie.NewDatabase(db))
+	if err != nil {
+		return fmt.Errorf("failed to open state trie at block %x: %v", blockHash, err)
+	}
+
+	enc := json.NewEncoder(w)
+	first := true
+	it := trie.NewIterator(accountTrie.NodeIterator(nil))
+	for it.Next() {
+		addrBytes := accountTrie.GetKey(it.Key)
+		if addrBytes == nil {
+			continue // no preimage on hand for this hashed key; nothing we can label it with
+		}
+		addr := common.BytesToAddress(addrBytes)
+
+		var data state.Account
+		if err := rlp.DecodeBytes(it.Value, &data); err != nil {
+			return fmt.Errorf("failed to decode account %s: %v", addr.Hex(), err)
+		}
+
+		var code []byte
+		if !bytes.Equal(data.CodeHash, emptyCodeHash) {
+			code = rawdb.ReadCode(db, common.BytesToHash(data.CodeHash))
+		}
+
+		storage := make(map[common.Hash]common.Hash)
+		if data.Root != emptyRoot {
+			storageTrie, err := trie.NewSecure(data.Root, trie.NewDatabase(db))
+			if err != nil {
+				return fmt.Errorf("failed to open storage trie for %s: %v", addr.Hex(), err)
+			}
+			sit := trie.NewIterator(storageTrie.NodeIterator(nil))
+			for sit.Next() {
+				keyBytes := storageTrie.GetKey(sit.Key)
+				if keyBytes == nil {
+					continue
+				}
+				var value []byte
+				if err := rlp.DecodeBytes(sit.Value, &value); err != nil {
+					return fmt.Errorf("failed to decode storage value for %s: %v", addr.Hex(), err)
+				}
+				storage[common.BytesToHash(keyBytes)] = common.BytesToHash(value)
+			}
+		}
+
+		account := GenesisAccount{
+			Code:    code,
+			Storage: storage,
+			Balance: data.Balance,
+			Nonce:   data.Nonce,
+		}
+
+		if !first {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		first = false
+		addrJSON, err := json.Marshal(common.UnprefixedAddress(addr))
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(addrJSON); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, ":"); err != nil {
+			return err
+		}
+		if err := enc.Encode(account); err != nil {
+			return err
+		}
+	}
+
+	_, err = io.WriteString(w, "}}")
+	return err
+}
@@ -0,0 +1,338 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	blockfactory "github.com/harmony-one/harmony/block/factory"
+	"github.com/harmony-one/harmony/core/rawdb"
+	"github.com/harmony-one/harmony/core/state"
+	"github.com/harmony-one/harmony/internal/params"
+	"github.com/harmony-one/harmony/numeric"
+	"github.com/harmony-one/harmony/shard"
+)
+
+func testGenesis(alloc GenesisAlloc) *Genesis {
+	return &Genesis{
+		Config:   params.AllProtocolChanges,
+		Factory:  blockfactory.NewFactory(params.AllProtocolChanges),
+		GasLimit: 8000000,
+		Alloc:    alloc,
+	}
+}
+
+func TestSetupGenesisBlockEmptyDBNilGenesis(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	config, hash, err := SetupGenesisBlock(db, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config == nil {
+		t.Fatal("expected a non-nil chain config")
+	}
+	if (hash == common.Hash{}) {
+		t.Fatal("expected a non-zero genesis hash")
+	}
+}
+
+func TestSetupGenesisBlockEmptyDBCustomGenesis(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	genesis := testGenesis(GenesisAlloc{
+		common.HexToAddress("0x01"): {Balance: big.NewInt(1)},
+	})
+	config, hash, err := SetupGenesisBlock(db, genesis)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config != genesis.Config {
+		t.Fatal("expected the returned config to be the supplied genesis config")
+	}
+	if want := genesis.ToBlock(nil).Hash(); hash != want {
+		t.Fatalf("hash = %x, want %x", hash, want)
+	}
+}
+
+func TestSetupGenesisBlockCompatibleRestart(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	genesis := testGenesis(GenesisAlloc{
+		common.HexToAddress("0x01"): {Balance: big.NewInt(1)},
+	})
+	if _, _, err := SetupGenesisBlock(db, genesis); err != nil {
+		t.Fatalf("initial setup failed: %v", err)
+	}
+
+	// A plain node restart supplies the identical genesis spec again; this
+	// must succeed and return the same hash rather than erroring out or,
+	// as it once did, crashing the process while hashing the candidate
+	// genesis for comparison.
+	config, hash, err := SetupGenesisBlock(db, genesis)
+	if err != nil {
+		t.Fatalf("restart with identical genesis failed: %v", err)
+	}
+	if config == nil {
+		t.Fatal("expected a non-nil chain config")
+	}
+	if want := genesis.ToBlock(nil).Hash(); hash != want {
+		t.Fatalf("hash = %x, want %x", hash, want)
+	}
+}
+
+func TestSetupGenesisBlockMismatch(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	first := testGenesis(GenesisAlloc{
+		common.HexToAddress("0x01"): {Balance: big.NewInt(1)},
+	})
+	if _, _, err := SetupGenesisBlock(db, first); err != nil {
+		t.Fatalf("initial setup failed: %v", err)
+	}
+
+	second := testGenesis(GenesisAlloc{
+		common.HexToAddress("0x02"): {Balance: big.NewInt(2)},
+	})
+	if _, _, err := SetupGenesisBlock(db, second); err == nil {
+		t.Fatal("expected an error for an incompatible stored genesis")
+	} else if _, ok := err.(*GenesisMismatchError); !ok {
+		t.Fatalf("expected *GenesisMismatchError, got %T (%v)", err, err)
+	}
+}
+
+// TestToBlockRegistersValidatorsBeforeDelegations covers the two-validator,
+// cross-delegating case that used to fail intermittently: ToBlock ranged
+// over g.Alloc once and applied a delegation as soon as it was reached,
+// which panicked with "unknown validator" whenever Go's randomized map
+// iteration visited the delegator before the validator it delegates to.
+func TestToBlockRegistersValidatorsBeforeDelegations(t *testing.T) {
+	validatorA := common.HexToAddress("0x01")
+	validatorB := common.HexToAddress("0x02")
+	delegator := common.HexToAddress("0x03")
+
+	newValidator := func(selfDelegation int64, blsKey byte) *GenesisValidator {
+		return &GenesisValidator{
+			BLSPubKeys:              []shard.BLSPublicKey{{blsKey}},
+			MinSelfDelegation:       big.NewInt(1),
+			MaxTotalDelegation:      big.NewInt(10000),
+			CommissionRate:          numeric.ZeroDec(),
+			CommissionMaxRate:       numeric.OneDec(),
+			CommissionMaxChangeRate: numeric.OneDec(),
+			SelfDelegation:          big.NewInt(selfDelegation),
+		}
+	}
+
+	db := rawdb.NewMemoryDatabase()
+	genesis := testGenesis(GenesisAlloc{
+		validatorA: {Balance: big.NewInt(500), Validator: newValidator(500, 1)},
+		validatorB: {Balance: big.NewInt(500), Validator: newValidator(500, 2)},
+		delegator: {
+			Balance: big.NewInt(1000),
+			Delegations: []GenesisDelegation{
+				{ValidatorAddress: validatorA, Amount: big.NewInt(100)},
+				{ValidatorAddress: validatorB, Amount: big.NewInt(200)},
+			},
+		},
+	})
+
+	block := genesis.ToBlock(db)
+
+	statedb, err := state.New(block.Root(), state.NewDatabase(db))
+	if err != nil {
+		t.Fatalf("failed to reopen state at genesis root: %v", err)
+	}
+	for _, tc := range []struct {
+		addr           common.Address
+		wantDelegation int64
+	}{
+		{validatorA, 100},
+		{validatorB, 200},
+	} {
+		wrapper, err := state.ValidatorWrapper(statedb, tc.addr)
+		if err != nil {
+			t.Fatalf("validator %s was not registered: %v", tc.addr.Hex(), err)
+		}
+		if len(wrapper.Delegations) != 2 {
+			t.Fatalf("validator %s: got %d delegations, want 2 (self + delegator)", tc.addr.Hex(), len(wrapper.Delegations))
+		}
+		if got := wrapper.Delegations[1].Amount; got.Cmp(big.NewInt(tc.wantDelegation)) != 0 {
+			t.Fatalf("validator %s: delegation amount = %s, want %d", tc.addr.Hex(), got, tc.wantDelegation)
+		}
+	}
+	if got, want := statedb.GetBalance(delegator), big.NewInt(700); got.Cmp(want) != 0 {
+		t.Fatalf("delegator balance = %s, want %s", got, want)
+	}
+}
+
+// TestDumpGenesisRoundTrip checks that a Genesis dumped from committed chain
+// state can be committed again and reproduces the same genesis block, i.e.
+// DumpGenesis's reconstruction doesn't silently drop or reorder anything
+// that feeds into the block hash.
+func TestDumpGenesisRoundTrip(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	addr := common.HexToAddress("0x01")
+	genesis := testGenesis(GenesisAlloc{
+		addr: {
+			Balance: big.NewInt(1000),
+			Nonce:   1,
+			Code:    []byte{0x60, 0x00},
+			Storage: map[common.Hash]common.Hash{
+				common.HexToHash("0x01"): common.HexToHash("0x02"),
+			},
+		},
+	})
+	block, err := genesis.Commit(db)
+	if err != nil {
+		t.Fatalf("commit failed: %v", err)
+	}
+
+	dumped, err := DumpGenesis(db, block.Hash())
+	if err != nil {
+		t.Fatalf("DumpGenesis failed: %v", err)
+	}
+
+	redb := rawdb.NewMemoryDatabase()
+	reblock, err := dumped.Commit(redb)
+	if err != nil {
+		t.Fatalf("re-commit of dumped genesis failed: %v", err)
+	}
+	if reblock.Hash() != block.Hash() {
+		t.Fatalf("re-committed hash = %x, want %x", reblock.Hash(), block.Hash())
+	}
+}
+
+// TestDumpGenesisToJSONMatchesDumpGenesis checks that streaming the account
+// trie directly, as DumpGenesisToJSON does, produces the same alloc that
+// DumpGenesis builds from statedb.RawDump.
+func TestDumpGenesisToJSONMatchesDumpGenesis(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	addr := common.HexToAddress("0x01")
+	genesis := testGenesis(GenesisAlloc{
+		addr: {Balance: big.NewInt(1000), Nonce: 1},
+	})
+	block, err := genesis.Commit(db)
+	if err != nil {
+		t.Fatalf("commit failed: %v", err)
+	}
+
+	dumped, err := DumpGenesis(db, block.Hash())
+	if err != nil {
+		t.Fatalf("DumpGenesis failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := DumpGenesisToJSON(&buf, db, block.Hash()); err != nil {
+		t.Fatalf("DumpGenesisToJSON failed: %v", err)
+	}
+	var streamed Genesis
+	if err := streamed.DecodeStream(&buf); err != nil {
+		t.Fatalf("decoding DumpGenesisToJSON output failed: %v", err)
+	}
+
+	if len(streamed.Alloc) != len(dumped.Alloc) {
+		t.Fatalf("streamed alloc has %d accounts, want %d", len(streamed.Alloc), len(dumped.Alloc))
+	}
+	got, want := streamed.Alloc[addr], dumped.Alloc[addr]
+	if got.Balance.Cmp(want.Balance) != 0 {
+		t.Fatalf("balance = %s, want %s", got.Balance, want.Balance)
+	}
+	if got.Nonce != want.Nonce {
+		t.Fatalf("nonce = %d, want %d", got.Nonce, want.Nonce)
+	}
+}
+
+// TestDecodeStreamMatchesUnmarshalJSON checks that the streaming decoder
+// parses the same genesis.json identically to the plain json.Unmarshal path
+// that UnmarshalJSON drives, for a file containing a validator and a
+// cross-delegation.
+func TestDecodeStreamMatchesUnmarshalJSON(t *testing.T) {
+	validator := common.HexToAddress("0x01")
+	delegator := common.HexToAddress("0x02")
+	genesis := testGenesis(GenesisAlloc{
+		validator: {
+			Balance: big.NewInt(500),
+			Validator: &GenesisValidator{
+				BLSPubKeys:              []shard.BLSPublicKey{{1}},
+				MinSelfDelegation:       big.NewInt(1),
+				MaxTotalDelegation:      big.NewInt(10000),
+				CommissionRate:          numeric.ZeroDec(),
+				CommissionMaxRate:       numeric.OneDec(),
+				CommissionMaxChangeRate: numeric.OneDec(),
+				SelfDelegation:          big.NewInt(500),
+			},
+		},
+		delegator: {
+			Balance: big.NewInt(1000),
+			Delegations: []GenesisDelegation{
+				{ValidatorAddress: validator, Amount: big.NewInt(100)},
+			},
+		},
+	})
+
+	data, err := json.Marshal(genesis)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+
+	var viaUnmarshal Genesis
+	if err := json.Unmarshal(data, &viaUnmarshal); err != nil {
+		t.Fatalf("json.Unmarshal failed: %v", err)
+	}
+	var viaStream Genesis
+	if err := viaStream.DecodeStream(bytes.NewReader(data)); err != nil {
+		t.Fatalf("DecodeStream failed: %v", err)
+	}
+
+	if len(viaStream.Alloc) != len(viaUnmarshal.Alloc) {
+		t.Fatalf("streamed alloc has %d accounts, want %d", len(viaStream.Alloc), len(viaUnmarshal.Alloc))
+	}
+	for addr, wantAccount := range viaUnmarshal.Alloc {
+		gotAccount, ok := viaStream.Alloc[addr]
+		if !ok {
+			t.Fatalf("streamed alloc missing account %s", addr.Hex())
+		}
+		if gotAccount.Balance.Cmp(wantAccount.Balance) != 0 {
+			t.Fatalf("account %s: balance = %s, want %s", addr.Hex(), gotAccount.Balance, wantAccount.Balance)
+		}
+		if (gotAccount.Validator == nil) != (wantAccount.Validator == nil) {
+			t.Fatalf("account %s: validator presence mismatch", addr.Hex())
+		}
+		if gotAccount.Validator != nil {
+			if gotAccount.Validator.SelfDelegation.Cmp(wantAccount.Validator.SelfDelegation) != 0 {
+				t.Fatalf("account %s: self-delegation = %s, want %s", addr.Hex(), gotAccount.Validator.SelfDelegation, wantAccount.Validator.SelfDelegation)
+			}
+		}
+		if len(gotAccount.Delegations) != len(wantAccount.Delegations) {
+			t.Fatalf("account %s: got %d delegations, want %d", addr.Hex(), len(gotAccount.Delegations), len(wantAccount.Delegations))
+		}
+	}
+}
+
+// TestDeveloperGenesisBlockRegistersValidator checks that the validator
+// DeveloperGenesisBlock auto-generates actually registers through ToBlock,
+// rather than only looking plausible in the returned Genesis struct.
+func TestDeveloperGenesisBlockRegistersValidator(t *testing.T) {
+	faucet := common.HexToAddress("0x99")
+	db := rawdb.NewMemoryDatabase()
+	genesis := DeveloperGenesisBlock(1, faucet, 0)
+
+	block := genesis.ToBlock(db)
+	statedb, err := state.New(block.Root(), state.NewDatabase(db))
+	if err != nil {
+		t.Fatalf("failed to reopen state at genesis root: %v", err)
+	}
+
+	if got, want := statedb.GetBalance(faucet), developerFaucetBalance; got.Cmp(want) != 0 {
+		t.Fatalf("faucet balance = %s, want %s", got, want)
+	}
+
+	wrapper, err := state.ValidatorWrapper(statedb, developerValidatorAddress)
+	if err != nil {
+		t.Fatalf("developer validator was not registered: %v", err)
+	}
+	if len(wrapper.Delegations) != 1 {
+		t.Fatalf("got %d delegations, want 1 (self)", len(wrapper.Delegations))
+	}
+	if got, want := wrapper.Delegations[0].Amount, big.NewInt(1); got.Cmp(want) != 0 {
+		t.Fatalf("self-delegation = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,156 @@
+package params
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// ChainConfig is the core config which determines the blockchain settings.
+//
+// ChainConfig is stored in the database on a per-block basis. This means
+// that any network, identified by its genesis block, can have its own set
+// of configuration options.
+//
+// Unlike Ethereum, where forks activate at a block number, Harmony forks
+// activate at an epoch number: epoch, not block, is the unit committee
+// reshuffles and shard assignments operate on, so a fork boundary needs to
+// line up with an epoch boundary. Use CalcEpochNumber to convert a block
+// number into the epoch it falls in before comparing it against any of the
+// epoch fields below.
+type ChainConfig struct {
+	ChainID *big.Int `json:"chainId"`
+
+	// BlocksPerEpoch is the number of blocks in one epoch.
+	BlocksPerEpoch uint64 `json:"blocksPerEpoch"`
+
+	EIP155Epoch     *big.Int `json:"eip155Epoch,omitempty"`
+	CrossTxEpoch    *big.Int `json:"crossTxEpoch,omitempty"`
+	PreStakingEpoch *big.Int `json:"preStakingEpoch,omitempty"`
+	StakingEpoch    *big.Int `json:"stakingEpoch,omitempty"`
+	CrossLinkEpoch  *big.Int `json:"crossLinkEpoch,omitempty"`
+	S3Epoch         *big.Int `json:"s3Epoch,omitempty"`
+}
+
+// GenesisGasLimit is the gas limit used by DefaultGenesisBlock.
+const GenesisGasLimit uint64 = 8000000
+
+var (
+	// AllProtocolChanges is a ChainConfig with every fork enabled from
+	// epoch 0. It is used by tests and by networks, such as the developer
+	// --dev chain, that want the full feature set available immediately
+	// rather than scheduled against main-net's actual activation epochs.
+	AllProtocolChanges = &ChainConfig{
+		ChainID:         big.NewInt(1),
+		BlocksPerEpoch:  16384,
+		EIP155Epoch:     big.NewInt(0),
+		CrossTxEpoch:    big.NewInt(0),
+		PreStakingEpoch: big.NewInt(0),
+		StakingEpoch:    big.NewInt(0),
+		CrossLinkEpoch:  big.NewInt(0),
+		S3Epoch:         big.NewInt(0),
+	}
+
+	// MainnetChainConfig is the chain configuration for Harmony main-net,
+	// with each fork scheduled at the epoch it actually activated on.
+	MainnetChainConfig = &ChainConfig{
+		ChainID:         big.NewInt(1666600000),
+		BlocksPerEpoch:  16384,
+		EIP155Epoch:     big.NewInt(0),
+		CrossTxEpoch:    big.NewInt(1),
+		S3Epoch:         big.NewInt(176),
+		PreStakingEpoch: big.NewInt(184),
+		StakingEpoch:    big.NewInt(186),
+		CrossLinkEpoch:  big.NewInt(186),
+	}
+)
+
+// CalcEpochNumber returns the epoch that blockNum falls in, given c's
+// BlocksPerEpoch. A zero BlocksPerEpoch (no epoch schedule configured)
+// always resolves to the genesis epoch.
+func (c *ChainConfig) CalcEpochNumber(blockNum uint64) *big.Int {
+	if c.BlocksPerEpoch == 0 {
+		return big.NewInt(0)
+	}
+	return new(big.Int).SetUint64(blockNum / c.BlocksPerEpoch)
+}
+
+// ConfigCompatError is raised if the locally-stored chain configuration
+// disagrees with the one a node is about to write, for a fork that has
+// already activated as of the current head. What is the name of the
+// disagreeing field; RewindTo is the block the node must roll back to
+// before the new configuration can safely take effect.
+type ConfigCompatError struct {
+	What string
+
+	StoredConfig, NewConfig *big.Int
+	RewindTo                uint64
+}
+
+func (err *ConfigCompatError) Error() string {
+	return fmt.Sprintf("mismatching %s in database (have %d, want %d, rewindto %d)", err.What, err.StoredConfig, err.NewConfig, err.RewindTo)
+}
+
+// CheckCompatible checks whether scheduled fork transitions have been
+// imported with a mismatching chain configuration. If yes, the error is a
+// non-nil *ConfigCompatError identifying the first fork whose activation
+// epoch has already been passed (as of height) and disagrees between c and
+// newcfg; forks that haven't activated yet may be freely rescheduled.
+func (c *ChainConfig) CheckCompatible(newcfg *ChainConfig, height uint64) *ConfigCompatError {
+	headEpoch := c.CalcEpochNumber(height)
+
+	for _, fork := range []struct {
+		name        string
+		storedEpoch *big.Int
+		newEpoch    *big.Int
+	}{
+		{"EIP155Epoch", c.EIP155Epoch, newcfg.EIP155Epoch},
+		{"CrossTxEpoch", c.CrossTxEpoch, newcfg.CrossTxEpoch},
+		{"S3Epoch", c.S3Epoch, newcfg.S3Epoch},
+		{"PreStakingEpoch", c.PreStakingEpoch, newcfg.PreStakingEpoch},
+		{"StakingEpoch", c.StakingEpoch, newcfg.StakingEpoch},
+		{"CrossLinkEpoch", c.CrossLinkEpoch, newcfg.CrossLinkEpoch},
+	} {
+		if err := c.epochIncompatible(fork.name, fork.storedEpoch, fork.newEpoch, headEpoch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// epochIncompatible reports a ConfigCompatError if storedEpoch and newEpoch
+// disagree on when (or whether) a fork activates and the fork has already
+// activated under storedEpoch as of headEpoch. A fork that hasn't activated
+// yet can be rescheduled without consequence, since no block has been
+// produced under it.
+func (c *ChainConfig) epochIncompatible(name string, storedEpoch, newEpoch, headEpoch *big.Int) *ConfigCompatError {
+	if epochEqual(storedEpoch, newEpoch) {
+		return nil
+	}
+	if storedEpoch == nil || storedEpoch.Cmp(headEpoch) > 0 {
+		return nil
+	}
+	return c.newCompatError(name, storedEpoch, newEpoch)
+}
+
+func (c *ChainConfig) newCompatError(what string, storedEpoch, newEpoch *big.Int) *ConfigCompatError {
+	var rewindTo uint64
+	if storedEpoch.Sign() > 0 {
+		rewindTo = (storedEpoch.Uint64() - 1) * c.BlocksPerEpoch
+	}
+	return &ConfigCompatError{
+		What:         what,
+		StoredConfig: storedEpoch,
+		NewConfig:    newEpoch,
+		RewindTo:     rewindTo,
+	}
+}
+
+func epochEqual(x, y *big.Int) bool {
+	if x == nil {
+		return y == nil
+	}
+	if y == nil {
+		return false
+	}
+	return x.Cmp(y) == 0
+}
@@ -0,0 +1,60 @@
+package params
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestCheckCompatibleCompatibleUpgrade(t *testing.T) {
+	stored := &ChainConfig{
+		BlocksPerEpoch: 10,
+		EIP155Epoch:    big.NewInt(0),
+		StakingEpoch:   big.NewInt(5),
+	}
+	// The new config only moves a fork that hasn't activated yet (epoch 5,
+	// with head still in epoch 2); that's a harmless reschedule.
+	newcfg := &ChainConfig{
+		BlocksPerEpoch: 10,
+		EIP155Epoch:    big.NewInt(0),
+		StakingEpoch:   big.NewInt(6),
+	}
+	if err := stored.CheckCompatible(newcfg, 25); err != nil {
+		t.Fatalf("expected no error for a not-yet-activated fork move, got %v", err)
+	}
+}
+
+func TestCheckCompatibleIncompatibleRewind(t *testing.T) {
+	stored := &ChainConfig{
+		BlocksPerEpoch: 10,
+		EIP155Epoch:    big.NewInt(0),
+		StakingEpoch:   big.NewInt(2),
+	}
+	// StakingEpoch already activated (head is in epoch 5); moving it is a
+	// consensus break and must be reported as incompatible.
+	newcfg := &ChainConfig{
+		BlocksPerEpoch: 10,
+		EIP155Epoch:    big.NewInt(0),
+		StakingEpoch:   big.NewInt(3),
+	}
+	err := stored.CheckCompatible(newcfg, 55)
+	if err == nil {
+		t.Fatal("expected a ConfigCompatError for an already-activated fork move")
+	}
+	if err.What != "StakingEpoch" {
+		t.Fatalf("What = %q, want %q", err.What, "StakingEpoch")
+	}
+	if want := uint64(10); err.RewindTo != want {
+		t.Fatalf("RewindTo = %d, want %d", err.RewindTo, want)
+	}
+}
+
+func TestCalcEpochNumber(t *testing.T) {
+	c := &ChainConfig{BlocksPerEpoch: 10}
+	if got := c.CalcEpochNumber(25); got.Cmp(big.NewInt(2)) != 0 {
+		t.Fatalf("epoch = %s, want 2", got)
+	}
+	zero := &ChainConfig{}
+	if got := zero.CalcEpochNumber(25); got.Cmp(big.NewInt(0)) != 0 {
+		t.Fatalf("epoch with no BlocksPerEpoch = %s, want 0", got)
+	}
+}
@@ -0,0 +1,58 @@
+package node
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/harmony-one/harmony/core"
+	"github.com/harmony-one/harmony/core/rawdb"
+	"github.com/harmony-one/harmony/internal/params"
+	"github.com/harmony-one/harmony/internal/utils"
+)
+
+// InitGenesis reconciles the genesis specification supplied at startup
+// (nil meaning "use whatever is already stored, or the main-net default")
+// against chainDb. Operators upgrading a node with a newer genesis.json
+// get a GenesisMismatchError or ConfigCompatError back instead of the node
+// silently diverging from its peers.
+func InitGenesis(chainDb ethdb.Database, genesis *core.Genesis) (*params.ChainConfig, common.Hash, error) {
+	chainConfig, genesisHash, err := core.SetupGenesisBlock(chainDb, genesis)
+	if err != nil {
+		utils.Logger().Error().Err(err).Msg("failed to set up genesis block")
+		return chainConfig, genesisHash, err
+	}
+	utils.Logger().Info().
+		Str("genesisHash", genesisHash.Hex()).
+		Msg("genesis block ready")
+	return chainConfig, genesisHash, nil
+}
+
+// LoadGenesis reads the genesis specification at path, if any, and runs it
+// through InitGenesis against chainDb. path == "" means no explicit genesis
+// was supplied at startup (fall back to whatever is already stored, or the
+// main-net default). Reading goes through core.LoadGenesisFromFile rather
+// than ioutil.ReadFile+json.Unmarshal so genesis.json is never held in
+// memory whole and its alloc entries are decoded one at a time.
+func LoadGenesis(chainDb ethdb.Database, path string) (*params.ChainConfig, common.Hash, error) {
+	var genesis *core.Genesis
+	if path != "" {
+		var err error
+		genesis, err = core.LoadGenesisFromFile(path)
+		if err != nil {
+			return nil, common.Hash{}, err
+		}
+	}
+	return InitGenesis(chainDb, genesis)
+}
+
+// InitDevGenesis sets up the in-memory chain that backs `harmony --dev`:
+// a throwaway database seeded with core.DeveloperGenesisBlock, so local
+// smart-contract testing works without a full localnet config, analogous
+// to geth's --dev. The returned database is the node's chain database for
+// the lifetime of the process; there is nothing on disk to reuse across
+// restarts.
+func InitDevGenesis(period uint64, faucet common.Address, shardID uint32) (ethdb.Database, *params.ChainConfig, common.Hash, error) {
+	chainDb := rawdb.NewMemoryDatabase()
+	genesis := core.DeveloperGenesisBlock(period, faucet, shardID)
+	chainConfig, genesisHash, err := InitGenesis(chainDb, genesis)
+	return chainDb, chainConfig, genesisHash, err
+}